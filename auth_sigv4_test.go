@@ -0,0 +1,97 @@
+package gremlin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// staticCreds is a fixed aws.CredentialsProvider for pinning a SigV4 test
+// vector, standing in for the real providers (env, IMDS, STS) production
+// code uses.
+type staticCreds aws.Credentials
+
+func (c staticCreds) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return aws.Credentials(c), nil
+}
+
+// TestSigV4DialHeaderVanilla pins sigV4DialHeader's canonical request and
+// Authorization header against a fixed (region, access key, secret key,
+// date) tuple, worked out by hand from the building blocks this file signs
+// with (sha256Hex, hmacSHA256, sigV4SigningKey) so a change to the signing
+// logic can't silently produce a different, still-internally-consistent
+// signature.
+func TestSigV4DialHeaderVanilla(t *testing.T) {
+	restore := sigV4Now
+	defer func() { sigV4Now = restore }()
+	sigV4Now = func() time.Time {
+		return time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+	}
+
+	auth := &AuthInfo{
+		SigV4Region: "us-east-1",
+		SigV4Creds: staticCreds{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+		},
+	}
+
+	const wantAuthorization = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/neptune-db/aws4_request, SignedHeaders=host;x-amz-date, Signature=" +
+		"c82dd0d41e5db3a867c4be80a796848cb0423b836048d06ae646e6ac0942ec76"
+
+	header, err := sigV4DialHeader(context.Background(), "wss://example.amazonaws.com/gremlin", auth)
+	if err != nil {
+		t.Fatalf("sigV4DialHeader: %v", err)
+	}
+
+	if got := header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Fatalf("X-Amz-Date = %q, want %q", got, "20150830T123600Z")
+	}
+	if got := header.Get("Host"); got != "example.amazonaws.com" {
+		t.Fatalf("Host = %q, want %q", got, "example.amazonaws.com")
+	}
+	if got := header.Get("X-Amz-Security-Token"); got != "" {
+		t.Fatalf("X-Amz-Security-Token = %q, want empty for creds without a session token", got)
+	}
+	if got := header.Get("Authorization"); got != wantAuthorization {
+		t.Fatalf("Authorization =\n%q\nwant\n%q", got, wantAuthorization)
+	}
+}
+
+// TestSigV4DialHeaderSessionToken checks that a session token on the
+// credentials is both carried as X-Amz-Security-Token and included in the
+// Authorization header's SignedHeaders, since it changes the canonical
+// request.
+func TestSigV4DialHeaderSessionToken(t *testing.T) {
+	restore := sigV4Now
+	defer func() { sigV4Now = restore }()
+	sigV4Now = func() time.Time {
+		return time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+	}
+
+	auth := &AuthInfo{
+		SigV4Region: "us-east-1",
+		SigV4Creds: staticCreds{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY",
+			SessionToken:    "AQoDYXdzEJr...EXAMPLETOKEN",
+		},
+	}
+
+	header, err := sigV4DialHeader(context.Background(), "wss://example.amazonaws.com/gremlin", auth)
+	if err != nil {
+		t.Fatalf("sigV4DialHeader: %v", err)
+	}
+
+	if got := header.Get("X-Amz-Security-Token"); got != "AQoDYXdzEJr...EXAMPLETOKEN" {
+		t.Fatalf("X-Amz-Security-Token = %q, want the session token", got)
+	}
+
+	const wantAuthorization = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/neptune-db/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=" +
+		"5fc1f36d65ad86e96d2fc7c10210d6689ba494c4d3b1d2402e48696b1eee1fba"
+	if got := header.Get("Authorization"); got != wantAuthorization {
+		t.Fatalf("Authorization =\n%q\nwant\n%q", got, wantAuthorization)
+	}
+}
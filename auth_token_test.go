@@ -0,0 +1,75 @@
+package gremlin
+
+import (
+	"context"
+	"testing"
+)
+
+// TestSASLResponseToken checks the SASL response built for a token-based
+// authenticator, including the default anonymous mechanism and a named one
+// like GSSAPI/OAUTHBEARER.
+func TestSASLResponseToken(t *testing.T) {
+	cases := []struct {
+		name      string
+		mechanism string
+		want      string
+	}{
+		{"default mechanism", "", "\x00\x00sometoken"},
+		{"named mechanism", "OAUTHBEARER", "\x00OAUTHBEARER\x00sometoken"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			auth := &AuthInfo{
+				TokenSource: func(ctx context.Context) (string, error) { return "sometoken", nil },
+				Mechanism:   tc.mechanism,
+			}
+			got, err := saslResponse(context.Background(), auth)
+			if err != nil {
+				t.Fatalf("saslResponse: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("saslResponse = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestSASLResponseUserPass checks that saslResponse falls back to the plain
+// user/pass SASL response when no token source is configured.
+func TestSASLResponseUserPass(t *testing.T) {
+	auth := &AuthInfo{User: "alice", Pass: "hunter2"}
+	got, err := saslResponse(context.Background(), auth)
+	if err != nil {
+		t.Fatalf("saslResponse: %v", err)
+	}
+	want := "\x00alice\x00hunter2"
+	if string(got) != want {
+		t.Fatalf("saslResponse = %q, want %q", got, want)
+	}
+}
+
+// TestDialHeaderBearerToken checks that dialHeader injects an
+// Authorization: Bearer header when OptAuthToken is configured.
+func TestDialHeaderBearerToken(t *testing.T) {
+	opt := OptAuthToken(func(ctx context.Context) (string, error) { return "jwt-123", nil })
+	header, err := dialHeader("ws://localhost:8182/gremlin", opt)
+	if err != nil {
+		t.Fatalf("dialHeader: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "Bearer jwt-123" {
+		t.Fatalf("Authorization = %q, want %q", got, "Bearer jwt-123")
+	}
+}
+
+// TestDialHeaderNoAuth checks that dialHeader returns an empty header when
+// no SigV4 or token auth option is configured, e.g. plain user/pass auth
+// which is carried in the SASL response instead of the upgrade headers.
+func TestDialHeaderNoAuth(t *testing.T) {
+	header, err := dialHeader("ws://localhost:8182/gremlin", OptAuthUserPass("alice", "hunter2"))
+	if err != nil {
+		t.Fatalf("dialHeader: %v", err)
+	}
+	if got := header.Get("Authorization"); got != "" {
+		t.Fatalf("Authorization = %q, want empty for user/pass auth", got)
+	}
+}
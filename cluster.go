@@ -0,0 +1,433 @@
+package gremlin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	defaultMinPerHost          = 1
+	defaultMaxPerHost          = 4
+	defaultHealthCheckInterval = 30 * time.Second
+	clusterHealthCheckQuery    = `g.V().limit(0)`
+)
+
+// ClusterOpt configures a Cluster at construction time.
+type ClusterOpt func(*Cluster)
+
+// WithPoolSize sets the minimum (kept warm) and maximum (allowed on demand)
+// number of pooled connections per host.
+func WithPoolSize(min, max int) ClusterOpt {
+	return func(c *Cluster) {
+		c.minPerHost = min
+		c.maxPerHost = max
+	}
+}
+
+// WithAuth sets the authentication options used to dial every connection
+// opened by the cluster.
+func WithAuth(auth ...OptAuth) ClusterOpt {
+	return func(c *Cluster) {
+		c.auth = auth
+	}
+}
+
+// WithHealthCheckInterval overrides how often pooled connections are
+// health-checked in the background.
+func WithHealthCheckInterval(interval time.Duration) ClusterOpt {
+	return func(c *Cluster) {
+		c.healthCheckInterval = interval
+	}
+}
+
+// pooledConn wraps a GremlinConnection with the bookkeeping the Cluster
+// needs to load-balance and health-check it.
+type pooledConn struct {
+	conn    *GremlinConnection
+	healthy bool
+	inUse   bool
+}
+
+// hostPool is the set of pooled connections the Cluster maintains against a
+// single server.
+type hostPool struct {
+	mu       sync.Mutex
+	host     *url.URL
+	conns    []*pooledConn
+	draining bool
+}
+
+func (p *hostPool) inUseCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, pc := range p.conns {
+		if pc.inUse {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *hostPool) healthyCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, pc := range p.conns {
+		if pc.healthy {
+			n++
+		}
+	}
+	return n
+}
+
+// Cluster owns a pool of GremlinConnections spread across one or more
+// hosts. It periodically health-checks idle connections, sends each Exec
+// call to the healthy host with the fewest connections in use, and transparently
+// retries a query against another host if the one it picked turns out to be
+// down.
+type Cluster struct {
+	mu                  sync.Mutex
+	pools               []*hostPool
+	auth                []OptAuth
+	minPerHost          int
+	maxPerHost          int
+	healthCheckInterval time.Duration
+	closed              chan struct{}
+	ctx                 context.Context
+	cancelCtx           context.CancelFunc
+}
+
+// NewCluster builds a Cluster from the given server URLs. If no servers are
+// supplied, the GREMLIN_SERVERS environment variable is used instead.
+func NewCluster(s []string, opts ...ClusterOpt) (*Cluster, error) {
+	if len(s) == 0 {
+		connString := strings.TrimSpace(os.Getenv("GREMLIN_SERVERS"))
+		if connString == "" {
+			return nil, errors.New("No servers set. Configure servers to connect to using the GREMLIN_SERVERS environment variable.")
+		}
+		urls, err := SplitServers(connString)
+		if err != nil {
+			return nil, err
+		}
+		return newCluster(urls, opts...)
+	}
+	urls := make([]*url.URL, 0, len(s))
+	for _, v := range s {
+		u, err := url.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, u)
+	}
+	return newCluster(urls, opts...)
+}
+
+func newCluster(urls []*url.URL, opts ...ClusterOpt) (*Cluster, error) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	c := &Cluster{
+		minPerHost:          defaultMinPerHost,
+		maxPerHost:          defaultMaxPerHost,
+		healthCheckInterval: defaultHealthCheckInterval,
+		closed:              make(chan struct{}),
+		ctx:                 ctx,
+		cancelCtx:           cancelCtx,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, u := range urls {
+		pool := &hostPool{host: u}
+		for i := 0; i < c.minPerHost; i++ {
+			conn, err := NewGremlinConnection(u.String(), c.auth...)
+			if err != nil {
+				// The host may simply be down at startup; the health check
+				// loop will keep trying to bring it into rotation.
+				continue
+			}
+			pool.conns = append(pool.conns, &pooledConn{conn: conn, healthy: true})
+		}
+		c.pools = append(c.pools, pool)
+	}
+	if len(c.pools) == 0 {
+		return nil, errors.New("Could not establish connection. Please check your connection string and ensure at least one server is up.")
+	}
+	go c.healthCheckLoop()
+	return c, nil
+}
+
+// Get checks out a connection to a healthy, least-loaded host, dialing a
+// new one if needed. ctx bounds that dial; it has no effect on the
+// lifetime of the connection once returned. The caller must invoke the
+// returned release func once it is done with the connection.
+func (c *Cluster) Get(ctx context.Context) (*GremlinConnection, func(), error) {
+	pool, pc, err := c.checkout(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		pool.mu.Lock()
+		pc.inUse = false
+		pool.mu.Unlock()
+	}
+	return pc.conn, release, nil
+}
+
+// checkout picks the healthy pool with the fewest in-use connections and
+// returns an idle connection from it, dialing a new one (bounded by ctx) if
+// the pool has room to grow.
+func (c *Cluster) checkout(ctx context.Context) (*hostPool, *pooledConn, error) {
+	c.mu.Lock()
+	pools := c.pools
+	c.mu.Unlock()
+
+	var best *hostPool
+	bestLoad := -1
+	for _, pool := range pools {
+		pool.mu.Lock()
+		draining := pool.draining
+		pool.mu.Unlock()
+		if draining || pool.healthyCount() == 0 {
+			continue
+		}
+		load := pool.inUseCount()
+		if bestLoad == -1 || load < bestLoad {
+			best = pool
+			bestLoad = load
+		}
+	}
+	if best == nil {
+		return nil, nil, errors.New("gremlin: no healthy hosts available in cluster")
+	}
+
+	if pc := best.claimIdle(); pc != nil {
+		return best, pc, nil
+	}
+
+	best.mu.Lock()
+	if len(best.conns) >= c.maxPerHost {
+		best.mu.Unlock()
+		return nil, nil, errors.New("gremlin: cluster pool exhausted; all connections to the least-loaded host are in use")
+	}
+	best.mu.Unlock()
+
+	// Dial outside the lock: this is a blocking network operation, and
+	// holding best.mu across it would serialize every other caller of this
+	// pool (release, checkHealth, Cluster.Close) behind a potentially
+	// multi-minute dial to an unreachable host.
+	conn, err := newGremlinConnectionWithContext(ctx, best.host.String(), c.auth...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Re-acquire and re-check: another goroutine may have freed up an idle
+	// connection, or filled the pool to maxPerHost, while we were dialing.
+	if pc := best.claimIdle(); pc != nil {
+		conn.Close()
+		return best, pc, nil
+	}
+	best.mu.Lock()
+	defer best.mu.Unlock()
+	if len(best.conns) >= c.maxPerHost {
+		conn.Close()
+		return nil, nil, errors.New("gremlin: cluster pool exhausted; all connections to the least-loaded host are in use")
+	}
+	pc := &pooledConn{conn: conn, healthy: true, inUse: true}
+	best.conns = append(best.conns, pc)
+	return best, pc, nil
+}
+
+// claimIdle returns and marks in-use the first healthy, idle connection in
+// the pool, or nil if there isn't one.
+func (p *hostPool) claimIdle() *pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pc := range p.conns {
+		if pc.healthy && !pc.inUse {
+			pc.inUse = true
+			return pc
+		}
+	}
+	return nil
+}
+
+// Exec runs query against the cluster, transparently retrying on another
+// host if the host it picked turns out to have gone away. ctx bounds both
+// the dial (if one is needed) and the query itself.
+func (c *Cluster) Exec(ctx context.Context, query string) ([]byte, error) {
+	triedHosts := make(map[*hostPool]bool)
+	var lastErr error
+	for attempt := 0; attempt < len(c.pools); attempt++ {
+		pool, pc, err := c.checkout(ctx)
+		if err != nil {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		if triedHosts[pool] {
+			pool.mu.Lock()
+			pc.inUse = false
+			pool.mu.Unlock()
+			break
+		}
+		triedHosts[pool] = true
+
+		data, err := execWithDeadline(ctx, pc.conn, query)
+		pool.mu.Lock()
+		pc.inUse = false
+		pool.mu.Unlock()
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !isRetryableErr(err) {
+			return nil, err
+		}
+		c.markUnhealthy(pool, pc)
+	}
+	return nil, lastErr
+}
+
+// execWithDeadline runs query against conn, applying ctx's deadline (if
+// any) to the underlying websocket so a caller-supplied timeout actually
+// bounds the call instead of being silently ignored, the same technique
+// ResultStream.Close uses to interrupt an in-flight read.
+func execWithDeadline(ctx context.Context, conn *GremlinConnection, query string) ([]byte, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return conn.ExecQuery(query)
+	}
+	conn.Ws.SetReadDeadline(deadline)
+	conn.Ws.SetWriteDeadline(deadline)
+	defer conn.Ws.SetReadDeadline(time.Time{})
+	defer conn.Ws.SetWriteDeadline(time.Time{})
+	return conn.ExecQuery(query)
+}
+
+// markUnhealthy flags a connection as unhealthy so checkout skips it until
+// the next health check brings it back (or replaces it).
+func (c *Cluster) markUnhealthy(pool *hostPool, pc *pooledConn) {
+	pool.mu.Lock()
+	pc.healthy = false
+	pool.mu.Unlock()
+}
+
+// isRetryableErr reports whether err looks like a transient connectivity
+// problem worth retrying on a different host, as opposed to e.g. a Gremlin
+// server error response.
+func isRetryableErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.(*net.OpError); ok {
+		return true
+	}
+	if websocket.IsUnexpectedCloseError(err) || websocket.IsCloseError(err,
+		websocket.CloseNormalClosure,
+		websocket.CloseGoingAway,
+		websocket.CloseAbnormalClosure) {
+		return true
+	}
+	return err == websocket.ErrCloseSent
+}
+
+// healthCheckLoop periodically probes every pooled connection with the same
+// no-op query MaintainConnection uses, reconnecting or marking connections
+// healthy/unhealthy as appropriate, and dials any host that has fewer than
+// minPerHost connections.
+func (c *Cluster) healthCheckLoop() {
+	ticker := time.NewTicker(c.healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			c.checkHealth()
+		}
+	}
+}
+
+func (c *Cluster) checkHealth() {
+	c.mu.Lock()
+	pools := c.pools
+	c.mu.Unlock()
+
+	for _, pool := range pools {
+		pool.mu.Lock()
+		conns := make([]*pooledConn, len(pool.conns))
+		copy(conns, pool.conns)
+		host := pool.host
+		missing := c.minPerHost - len(pool.conns)
+		pool.mu.Unlock()
+
+		// Bring hosts that were down at startup, or just added by
+		// applyHosts, up to minPerHost connections.
+		for i := 0; i < missing; i++ {
+			conn, err := NewGremlinConnection(host.String(), c.auth...)
+			if err != nil {
+				break
+			}
+			pool.mu.Lock()
+			if pool.draining {
+				pool.mu.Unlock()
+				conn.Close()
+				break
+			}
+			pool.conns = append(pool.conns, &pooledConn{conn: conn, healthy: true})
+			pool.mu.Unlock()
+		}
+
+		for _, pc := range conns {
+			// Claim the connection for the duration of the check (and any
+			// reconnect) the same way checkout does, so an application
+			// caller can never be handed a connection the health check is
+			// concurrently reading/writing on.
+			pool.mu.Lock()
+			if pc.inUse {
+				pool.mu.Unlock()
+				continue
+			}
+			pc.inUse = true
+			pool.mu.Unlock()
+
+			_, err := pc.conn.ExecQuery(clusterHealthCheckQuery)
+			healthy := err == nil
+			if !healthy {
+				healthy = pc.conn.Reconnect(host.String()) == nil
+			}
+
+			pool.mu.Lock()
+			pc.healthy = healthy
+			pc.inUse = false
+			pool.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the health check loop (and, if running, the Consul watch's
+// in-flight long-poll) and closes every pooled connection.
+func (c *Cluster) Close() error {
+	close(c.closed)
+	c.cancelCtx()
+	var firstErr error
+	for _, pool := range c.pools {
+		pool.mu.Lock()
+		for _, pc := range pool.conns {
+			if err := pc.conn.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		pool.mu.Unlock()
+	}
+	return firstErr
+}
@@ -0,0 +1,186 @@
+package gremlin
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+const (
+	consulWatchTimeout       = 5 * time.Minute
+	consulMaxConsecutiveErrs = 5
+	consulRetryBackoff       = time.Second
+	drainPollInterval        = 100 * time.Millisecond
+)
+
+// NewClusterFromConsul builds a Cluster whose member list tracks the
+// healthy instances of service in Consul's catalog. A background watch
+// refreshes the cluster's hosts as they come and go; hosts that drop out
+// of the catalog are drained and closed without interrupting queries
+// already in flight against them, and hosts that appear are dialed by the
+// cluster's next health check. If the watch fails
+// repeatedly, the cluster falls back to the static GREMLIN_SERVERS
+// connection string rather than serving an increasingly stale view of the
+// catalog.
+func NewClusterFromConsul(client *api.Client, service string, opts ...ClusterOpt) (*Cluster, error) {
+	urls, lastIndex, err := consulServiceURLs(context.Background(), client, service, 0)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newCluster(urls, opts...)
+	if err != nil {
+		return nil, err
+	}
+	go c.watchConsul(client, service, lastIndex)
+	return c, nil
+}
+
+// consulServiceURLs queries Consul's health endpoint for passing instances
+// of service, blocking until waitIndex is stale (a Consul long-poll) when
+// waitIndex is non-zero. ctx bounds the long-poll so a canceled ctx (e.g.
+// from Cluster.Close) returns promptly instead of waiting out the full
+// consulWatchTimeout.
+func consulServiceURLs(ctx context.Context, client *api.Client, service string, waitIndex uint64) ([]*url.URL, uint64, error) {
+	qo := (&api.QueryOptions{
+		WaitIndex: waitIndex,
+		WaitTime:  consulWatchTimeout,
+	}).WithContext(ctx)
+	entries, meta, err := client.Health().Service(service, "", true, qo)
+	if err != nil {
+		return nil, waitIndex, err
+	}
+
+	urls := make([]*url.URL, 0, len(entries))
+	for _, entry := range entries {
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		u, err := url.Parse(fmt.Sprintf("ws://%s:%d", host, entry.Service.Port))
+		if err != nil {
+			return nil, waitIndex, err
+		}
+		urls = append(urls, u)
+	}
+	return urls, meta.LastIndex, nil
+}
+
+// watchConsul blocks on Consul's health watch for service, applying the
+// refreshed host list to the cluster whenever membership changes.
+func (c *Cluster) watchConsul(client *api.Client, service string, lastIndex uint64) {
+	consecutiveErrs := 0
+	for {
+		select {
+		case <-c.closed:
+			return
+		default:
+		}
+
+		urls, index, err := consulServiceURLs(c.ctx, client, service, lastIndex)
+		if err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			consecutiveErrs++
+			if consecutiveErrs >= consulMaxConsecutiveErrs {
+				c.fallBackToStaticServers()
+				consecutiveErrs = 0
+			}
+			time.Sleep(consulRetryBackoff)
+			continue
+		}
+
+		consecutiveErrs = 0
+		if index != lastIndex {
+			lastIndex = index
+			c.applyHosts(urls)
+		}
+	}
+}
+
+// fallBackToStaticServers applies the GREMLIN_SERVERS connection string as
+// the cluster's host list, used when the Consul watch can no longer be
+// trusted to reflect reality.
+func (c *Cluster) fallBackToStaticServers() {
+	connString := strings.TrimSpace(os.Getenv("GREMLIN_SERVERS"))
+	if connString == "" {
+		return
+	}
+	urls, err := SplitServers(connString)
+	if err != nil || len(urls) == 0 {
+		return
+	}
+	c.applyHosts(urls)
+}
+
+// applyHosts reconciles the cluster's pools with the given host list,
+// draining pools for hosts no longer present and adding empty pools for
+// newly added hosts, which the next health check dials.
+func (c *Cluster) applyHosts(urls []*url.URL) {
+	wanted := make(map[string]*url.URL, len(urls))
+	for _, u := range urls {
+		wanted[u.String()] = u
+	}
+
+	c.mu.Lock()
+	kept := make([]*hostPool, 0, len(c.pools))
+	var removed []*hostPool
+	existing := make(map[string]bool, len(c.pools))
+	for _, pool := range c.pools {
+		key := pool.host.String()
+		existing[key] = true
+		if _, ok := wanted[key]; ok {
+			kept = append(kept, pool)
+		} else {
+			removed = append(removed, pool)
+		}
+	}
+	for key, u := range wanted {
+		if !existing[key] {
+			kept = append(kept, &hostPool{host: u})
+		}
+	}
+	c.pools = kept
+	c.mu.Unlock()
+
+	for _, pool := range removed {
+		go c.drainPool(pool)
+	}
+}
+
+// drainPool stops handing pool's connections out and closes each one once
+// it is no longer in use, so a host dropping out of the Consul catalog
+// never cuts off a query that is already in flight against it.
+func (c *Cluster) drainPool(pool *hostPool) {
+	pool.mu.Lock()
+	pool.draining = true
+	pool.mu.Unlock()
+
+	for {
+		pool.mu.Lock()
+		idle := true
+		for _, pc := range pool.conns {
+			if pc.inUse {
+				idle = false
+				break
+			}
+		}
+		pool.mu.Unlock()
+		if idle {
+			break
+		}
+		time.Sleep(drainPollInterval)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for _, pc := range pool.conns {
+		pc.conn.Close()
+	}
+	pool.conns = nil
+}
@@ -0,0 +1,72 @@
+package gremlin
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	return u
+}
+
+// TestApplyHostsReconciles checks that applyHosts keeps pools for hosts
+// still present, adds empty pools for newly seen hosts (to be dialed by the
+// next health check), and drops pools for hosts no longer in the catalog.
+func TestApplyHostsReconciles(t *testing.T) {
+	kept := &hostPool{host: mustParseURL(t, "ws://kept:8182")}
+	dropped := &hostPool{host: mustParseURL(t, "ws://dropped:8182")}
+	c := &Cluster{pools: []*hostPool{kept, dropped}}
+
+	c.applyHosts([]*url.URL{
+		mustParseURL(t, "ws://kept:8182"),
+		mustParseURL(t, "ws://added:8182"),
+	})
+
+	if len(c.pools) != 2 {
+		t.Fatalf("got %d pools, want 2", len(c.pools))
+	}
+	byHost := make(map[string]*hostPool, len(c.pools))
+	for _, p := range c.pools {
+		byHost[p.host.String()] = p
+	}
+	if byHost["ws://kept:8182"] != kept {
+		t.Fatal("applyHosts replaced the pool for a host that's still present")
+	}
+	if _, ok := byHost["ws://added:8182"]; !ok {
+		t.Fatal("applyHosts did not add a pool for the newly seen host")
+	}
+	if _, ok := byHost["ws://dropped:8182"]; ok {
+		t.Fatal("applyHosts kept the pool for a host no longer in the catalog")
+	}
+}
+
+// TestApplyHostsDrainsRemovedPool checks that a pool dropped from the
+// catalog is marked draining (so checkout stops handing out its
+// connections) rather than being torn down synchronously out from under a
+// query still in flight against it.
+func TestApplyHostsDrainsRemovedPool(t *testing.T) {
+	dropped := &hostPool{host: mustParseURL(t, "ws://dropped:8182")}
+	c := &Cluster{pools: []*hostPool{dropped}}
+
+	c.applyHosts(nil)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		dropped.mu.Lock()
+		draining := dropped.draining
+		dropped.mu.Unlock()
+		if draining {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("drainPool never marked the removed pool as draining")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
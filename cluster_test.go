@@ -0,0 +1,53 @@
+package gremlin
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// TestClusterCheckoutSaturatedPoolNeverDoubleAssigns guards against a
+// connection being handed to two concurrent callers once a pool is
+// saturated, which would mean two goroutines reading/writing the same
+// underlying websocket.
+func TestClusterCheckoutSaturatedPoolNeverDoubleAssigns(t *testing.T) {
+	host, err := url.Parse("ws://localhost:8182")
+	if err != nil {
+		t.Fatalf("parse host: %v", err)
+	}
+	pool := &hostPool{
+		host: host,
+		conns: []*pooledConn{
+			{conn: &GremlinConnection{}, healthy: true},
+			{conn: &GremlinConnection{}, healthy: true},
+		},
+	}
+	c := &Cluster{pools: []*hostPool{pool}, maxPerHost: len(pool.conns)}
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	results := make(chan *pooledConn, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, pc, err := c.checkout(context.Background()); err == nil {
+				results <- pc
+			}
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	seen := make(map[*pooledConn]bool)
+	for pc := range results {
+		if seen[pc] {
+			t.Fatalf("checkout handed the same connection to two concurrent callers")
+		}
+		seen[pc] = true
+	}
+	if len(seen) != len(pool.conns) {
+		t.Fatalf("expected exactly %d successful checkouts for a saturated pool of %d connections, got %d", len(pool.conns), len(pool.conns), len(seen))
+	}
+}
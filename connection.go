@@ -1,6 +1,7 @@
 package gremlin
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -10,8 +11,8 @@ import (
 	"net/url"
 	"os"
 	"strings"
-	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/gorilla/websocket"
 )
 
@@ -31,18 +32,77 @@ type GremlinConnection struct {
 }
 
 func NewGremlinConnection(urlStr string, options ...OptAuth) (*GremlinConnection, error) {
+	return newGremlinConnectionWithContext(context.Background(), urlStr, options...)
+}
+
+// newGremlinConnectionWithContext is NewGremlinConnection with the dial
+// bounded by ctx, for callers (namely Cluster) that need a caller-supplied
+// deadline to actually cancel a hung dial instead of blocking for the OS's
+// default TCP-connect timeout.
+func newGremlinConnectionWithContext(ctx context.Context, urlStr string, options ...OptAuth) (*GremlinConnection, error) {
 	r, err := url.Parse(urlStr)
 	if err != nil {
 		return nil, err
 	}
-	dialer := websocket.Dialer{}
-	ws, _, err := dialer.Dial(urlStr, http.Header{})
+	header, err := dialHeader(urlStr, options...)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := dialContext(ctx, urlStr, header)
 	if err != nil {
 		return nil, err
 	}
 	return &GremlinConnection{Remote: r, Ws: ws, Auth: options}, nil
 }
 
+// dialError wraps a failed WebSocket upgrade with the HTTP status code the
+// server responded with, when one is available, so callers can tell a
+// transient failure (e.g. a 403 from an expired SigV4 signature) apart
+// from a permanent configuration error (404, 500, wrong region, ...).
+type dialError struct {
+	statusCode int
+	err        error
+}
+
+func (e *dialError) Error() string { return e.err.Error() }
+func (e *dialError) Unwrap() error { return e.err }
+
+func dial(urlStr string, header http.Header) (*websocket.Conn, error) {
+	return dialContext(context.Background(), urlStr, header)
+}
+
+func dialContext(ctx context.Context, urlStr string, header http.Header) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{}
+	ws, resp, err := dialer.DialContext(ctx, urlStr, header)
+	if err != nil && resp != nil {
+		return ws, &dialError{statusCode: resp.StatusCode, err: err}
+	}
+	return ws, err
+}
+
+// dialHeader builds the http.Header a WebSocket upgrade request to urlStr
+// should carry for the given auth options, e.g. the SigV4 headers
+// OptAuthSigV4 requires.
+func dialHeader(urlStr string, options ...OptAuth) (http.Header, error) {
+	auth, err := NewAuthInfo(options...)
+	if err != nil {
+		return nil, err
+	}
+	if auth.SigV4Creds != nil {
+		return sigV4DialHeader(context.Background(), urlStr, auth)
+	}
+	if auth.TokenSource != nil {
+		token, err := auth.TokenSource(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		header := http.Header{}
+		header.Set("Authorization", "Bearer "+token)
+		return header, nil
+	}
+	return http.Header{}, nil
+}
+
 func NewVerboseGremlinConnection(urlStr string, verboseLogging bool, options ...OptAuth) (*GremlinConnection, error) {
 	conn, err := NewGremlinConnection(urlStr, options...)
 	if err != nil {
@@ -135,8 +195,11 @@ func (c *GremlinConnection) ReadResponse() (data []byte, err error) {
 }
 
 func (c *GremlinConnection) Reconnect(urlStr string) error {
-	dialer := websocket.Dialer{}
-	ws, _, err := dialer.Dial(urlStr, http.Header{})
+	header, err := dialHeader(urlStr, c.Auth...)
+	if err != nil {
+		return err
+	}
+	ws, err := dial(urlStr, header)
 	c.Ws = ws
 	return err
 }
@@ -152,6 +215,19 @@ type AuthInfo struct {
 	ChallengeId string
 	User        string
 	Pass        string
+
+	// SigV4Region and SigV4Creds are set by OptAuthSigV4 to sign the
+	// WebSocket upgrade request for AWS Neptune's IAM authentication
+	// instead of SASL.
+	SigV4Region string
+	SigV4Creds  aws.CredentialsProvider
+
+	// TokenSource and Mechanism are set by OptAuthToken/OptAuthMechanism to
+	// authenticate with a bearer token instead of a username and password.
+	// TokenSource is called fresh on every connect and reconnect so
+	// short-lived tokens (e.g. an OIDC-issued JWT) stay valid.
+	TokenSource func(ctx context.Context) (string, error)
+	Mechanism   string
 }
 
 type OptAuth func(*AuthInfo) error
@@ -194,17 +270,38 @@ func OptAuthUserPass(user, pass string) OptAuth {
 	}
 }
 
+// OptAuthToken configures the connection to authenticate with a bearer
+// token instead of a username and password, e.g. a short-lived JWT from an
+// OIDC provider sitting in front of Gremlin Server. tokenSource is invoked
+// on every connect and reconnect so the token it returns never goes stale.
+func OptAuthToken(tokenSource func(ctx context.Context) (string, error)) OptAuth {
+	return func(auth *AuthInfo) error {
+		auth.TokenSource = tokenSource
+		return nil
+	}
+}
+
+// OptAuthMechanism names the SASL mechanism a token authenticated with
+// OptAuthToken should be presented as, e.g. "GSSAPI" or "OAUTHBEARER", for
+// servers whose custom authenticator dispatches on it. Leaving it unset
+// authenticates anonymously with just the token.
+func OptAuthMechanism(mechanism string) OptAuth {
+	return func(auth *AuthInfo) error {
+		auth.Mechanism = mechanism
+		return nil
+	}
+}
+
 // Authenticates the connection
 func (c *GremlinConnection) Authenticate(requestId string) ([]byte, error) {
 	auth, err := NewAuthInfo(c.Auth...)
 	if err != nil {
 		return nil, err
 	}
-	var sasl []byte
-	sasl = append(sasl, 0)
-	sasl = append(sasl, []byte(auth.User)...)
-	sasl = append(sasl, 0)
-	sasl = append(sasl, []byte(auth.Pass)...)
+	sasl, err := saslResponse(context.Background(), auth)
+	if err != nil {
+		return nil, err
+	}
 	saslEnc := base64.StdEncoding.EncodeToString(sasl)
 	args := &RequestArgs{Sasl: saslEnc}
 	authReq := &Request{
@@ -216,6 +313,29 @@ func (c *GremlinConnection) Authenticate(requestId string) ([]byte, error) {
 	return c.Exec(authReq)
 }
 
+// saslResponse builds the SASL response bytes for auth: `\0<mechanism>\0<token>`
+// when a token source is configured (mechanism is empty unless
+// OptAuthMechanism was used), or the plain `\0<user>\0<pass>` otherwise.
+func saslResponse(ctx context.Context, auth *AuthInfo) ([]byte, error) {
+	var sasl []byte
+	if auth.TokenSource != nil {
+		token, err := auth.TokenSource(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sasl = append(sasl, 0)
+		sasl = append(sasl, []byte(auth.Mechanism)...)
+		sasl = append(sasl, 0)
+		sasl = append(sasl, []byte(token)...)
+	} else {
+		sasl = append(sasl, 0)
+		sasl = append(sasl, []byte(auth.User)...)
+		sasl = append(sasl, 0)
+		sasl = append(sasl, []byte(auth.Pass)...)
+	}
+	return sasl, nil
+}
+
 // Send a dummy query to neptune
 // If there is a network error, attempt to reconnect
 func (c *GremlinConnection) MaintainConnection(urlStr string) error {
@@ -232,37 +352,30 @@ func (c *GremlinConnection) MaintainConnection(urlStr string) error {
 	}
 	// if it is a network error, attempt to reconnect
 	err = c.Reconnect(urlStr)
+	if err != nil && isForbiddenUpgrade(err) {
+		// A 403 on the upgrade most often means the SigV4 signature (or
+		// bearer token) we just dialed with had already expired; redial
+		// once more so Reconnect gets a chance to sign with fresh
+		// credentials instead of surfacing a transient auth failure as
+		// fatal.
+		err = c.Reconnect(urlStr)
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-var servers []*url.URL
-
-func NewCluster(s ...string) (err error) {
-	servers = nil
-	// If no arguments use environment variable
-	if len(s) == 0 {
-		connString := strings.TrimSpace(os.Getenv("GREMLIN_SERVERS"))
-		if connString == "" {
-			err = errors.New("No servers set. Configure servers to connect to using the GREMLIN_SERVERS environment variable.")
-			return
-		}
-		servers, err = SplitServers(connString)
-		return
-	}
-	// Else use the supplied servers
-	for _, v := range s {
-		var u *url.URL
-		if u, err = url.Parse(v); err != nil {
-			return
-		}
-		servers = append(servers, u)
-	}
-	return
+// isForbiddenUpgrade reports whether err is a dialError for a 403 response
+// to the WebSocket upgrade request, as opposed to some other dial failure
+// (bad URL, wrong region, server down, ...) that redialing won't fix.
+func isForbiddenUpgrade(err error) bool {
+	var de *dialError
+	return errors.As(err, &de) && de.statusCode == http.StatusForbidden
 }
 
+// SplitServers parses a comma-separated GREMLIN_SERVERS-style connection
+// string into the list of server URLs it names.
 func SplitServers(connString string) (servers []*url.URL, err error) {
 	serverStrings := strings.Split(connString, ",")
 	if len(serverStrings) < 1 {
@@ -278,21 +391,3 @@ func SplitServers(connString string) (servers []*url.URL, err error) {
 	}
 	return
 }
-
-func CreateConnection() (conn net.Conn, server *url.URL, err error) {
-	connEstablished := false
-	for _, s := range servers {
-		c, err := net.DialTimeout("tcp", s.Host, 1*time.Second)
-		if err != nil {
-			continue
-		}
-		connEstablished = true
-		conn = c
-		server = s
-		break
-	}
-	if !connEstablished {
-		err = errors.New("Could not establish connection. Please check your connection string and ensure at least one server is up.")
-	}
-	return
-}
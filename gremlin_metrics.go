@@ -0,0 +1,94 @@
+package gremlin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GremlinMetrics decorates a Gremlin_i with Prometheus metrics: a request
+// counter labeled by result status, a latency histogram, and an in-flight
+// gauge. It composes with GremlinOTel, e.g.
+// NewGremlinMetrics(NewGremlinOTel(conn, tp), registerer).
+type GremlinMetrics struct {
+	next     Gremlin_i
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// NewGremlinMetrics wraps next, registering its metrics with registerer.
+func NewGremlinMetrics(next Gremlin_i, registerer prometheus.Registerer) GremlinMetrics {
+	g := GremlinMetrics{
+		next: next,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "gremlin",
+			Name:      "requests_total",
+			Help:      "Total number of Gremlin queries executed, labeled by result status.",
+		}, []string{"status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "gremlin",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of Gremlin queries in seconds, labeled by result status.",
+		}, []string{"status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "gremlin",
+			Name:      "requests_in_flight",
+			Help:      "Number of Gremlin queries currently in flight.",
+		}),
+	}
+	registerer.MustRegister(g.requests, g.latency, g.inFlight)
+	return g
+}
+
+func (g GremlinMetrics) observe(start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = errorCode(err)
+	}
+	g.requests.WithLabelValues(status).Inc()
+	g.latency.WithLabelValues(status).Observe(time.Since(start).Seconds())
+}
+
+func (g GremlinMetrics) ExecQueryF(ctx context.Context, gremlinQuery GremlinQuery) (response string, err error) {
+	g.inFlight.Inc()
+	defer g.inFlight.Dec()
+	start := time.Now()
+	defer func() { g.observe(start, err) }()
+	return g.next.ExecQueryF(ctx, gremlinQuery)
+}
+
+func (g GremlinMetrics) StartMonitor(ctx context.Context, interval time.Duration) (err error) {
+	g.inFlight.Inc()
+	defer g.inFlight.Dec()
+	start := time.Now()
+	defer func() { g.observe(start, err) }()
+	return g.next.StartMonitor(ctx, interval)
+}
+
+func (g GremlinMetrics) Close(ctx context.Context) (err error) {
+	start := time.Now()
+	err = g.next.Close(ctx)
+	g.observe(start, err)
+	return err
+}
+
+// ExecStream forwards to next's ExecStream if it implements one, recording
+// the same metrics as ExecQueryF.
+func (g GremlinMetrics) ExecStream(ctx context.Context, query string) (*ResultStream, error) {
+	g.inFlight.Inc()
+	defer g.inFlight.Dec()
+	start := time.Now()
+
+	streamer, ok := g.next.(streamingGremlin)
+	if !ok {
+		err := errors.New("gremlin: underlying client does not support ExecStream")
+		g.observe(start, err)
+		return nil, err
+	}
+	stream, err := streamer.ExecStream(ctx, query)
+	g.observe(start, err)
+	return stream, err
+}
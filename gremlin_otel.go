@@ -0,0 +1,160 @@
+package gremlin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this package as the OpenTelemetry instrumentation
+// library for spans GremlinOTel creates.
+const tracerName = "github.com/MediaMath/gremlin"
+
+// StatementRedactor rewrites a query before it is attached to a span as the
+// db.statement attribute, e.g. to strip bind values or PII.
+type StatementRedactor func(query string) string
+
+// GremlinOTelOption configures a GremlinOTel at construction time.
+type GremlinOTelOption func(*GremlinOTel)
+
+// WithStatementRedactor sets the function used to transform a query before
+// it is recorded as the db.statement span attribute. Without one, queries
+// are attached verbatim.
+func WithStatementRedactor(redactor StatementRedactor) GremlinOTelOption {
+	return func(g *GremlinOTel) {
+		g.redactor = redactor
+	}
+}
+
+// GremlinOTel is an OpenTelemetry-based replacement for the deprecated
+// GremlinTracer. It wraps a Gremlin_i and emits spans tagged with the
+// db.system/db.statement/net.peer.name attributes for each call.
+type GremlinOTel struct {
+	next     Gremlin_i
+	tracer   trace.Tracer
+	redactor StatementRedactor
+}
+
+// NewGremlinOTel wraps next, creating spans from the tracer tp provides.
+func NewGremlinOTel(next Gremlin_i, tp trace.TracerProvider, opts ...GremlinOTelOption) GremlinOTel {
+	g := GremlinOTel{
+		next:   next,
+		tracer: tp.Tracer(tracerName),
+	}
+	for _, opt := range opts {
+		opt(&g)
+	}
+	return g
+}
+
+func (g GremlinOTel) remoteHost() string {
+	conn, ok := g.next.(*GremlinConnection)
+	if !ok || conn.Remote == nil {
+		return ""
+	}
+	return conn.Remote.Host
+}
+
+func (g GremlinOTel) statement(query string) string {
+	if g.redactor == nil {
+		return query
+	}
+	return g.redactor(query)
+}
+
+func (g GremlinOTel) startSpan(ctx context.Context, method, query string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("db.system", "gremlin")}
+	if query != "" {
+		attrs = append(attrs, attribute.String("db.statement", g.statement(query)))
+	}
+	if host := g.remoteHost(); host != "" {
+		attrs = append(attrs, attribute.String("net.peer.name", host))
+	}
+	return g.tracer.Start(ctx, method, trace.WithAttributes(attrs...))
+}
+
+func (g GremlinOTel) endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errorCode(err))
+	}
+	span.End()
+}
+
+// errorCode maps err back to the Gremlin status code it was constructed
+// from. Errors ErrorMsg doesn't know about fall back to a small, fixed set
+// of buckets rather than the raw error message, which (being unbounded and
+// often carrying dynamic data like addresses) is unsafe to use as a span
+// status or, worse, a Prometheus label value.
+func errorCode(err error) string {
+	for code, msg := range ErrorMsg {
+		if msg == err.Error() {
+			return fmt.Sprintf("%d", code)
+		}
+	}
+	return unknownErrorBucket(err)
+}
+
+// unknownErrorBucket classifies an error that isn't one of the known
+// Gremlin status codes into a small, fixed label set.
+func unknownErrorBucket(err error) string {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return "network_error"
+	}
+	if errors.Is(err, websocket.ErrBadHandshake) || errors.Is(err, websocket.ErrCloseSent) ||
+		websocket.IsUnexpectedCloseError(err) {
+		return "network_error"
+	}
+	return "unknown"
+}
+
+func (g GremlinOTel) ExecQueryF(ctx context.Context, gremlinQuery GremlinQuery) (response string, err error) {
+	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.ExecQueryF")
+	ctx, span := g.startSpan(ctx, method, fmt.Sprintf("%v", gremlinQuery))
+	defer func() { g.endSpan(span, err) }()
+	return g.next.ExecQueryF(ctx, gremlinQuery)
+}
+
+func (g GremlinOTel) StartMonitor(ctx context.Context, interval time.Duration) (err error) {
+	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.StartMonitor")
+	ctx, span := g.startSpan(ctx, method, "")
+	defer func() { g.endSpan(span, err) }()
+	return g.next.StartMonitor(ctx, interval)
+}
+
+func (g GremlinOTel) Close(ctx context.Context) (err error) {
+	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.Close")
+	ctx, span := g.startSpan(ctx, method, "")
+	defer func() { g.endSpan(span, err) }()
+	return g.next.Close(ctx)
+}
+
+// ExecStream forwards to next's ExecStream if it implements one, tracing
+// the call the same way ExecQueryF is traced.
+func (g GremlinOTel) ExecStream(ctx context.Context, query string) (*ResultStream, error) {
+	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.ExecStream")
+	ctx, span := g.startSpan(ctx, method, query)
+	var err error
+	defer func() { g.endSpan(span, err) }()
+
+	streamer, ok := g.next.(streamingGremlin)
+	if !ok {
+		err = errors.New("gremlin: underlying client does not support ExecStream")
+		return nil, err
+	}
+	var stream *ResultStream
+	stream, err = streamer.ExecStream(ctx, query)
+	return stream, err
+}
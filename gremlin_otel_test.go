@@ -0,0 +1,40 @@
+package gremlin
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+type fakeTimeoutErr struct{}
+
+func (fakeTimeoutErr) Error() string   { return "i/o timeout" }
+func (fakeTimeoutErr) Timeout() bool   { return true }
+func (fakeTimeoutErr) Temporary() bool { return true }
+
+// TestUnknownErrorBucket pins the fixed, small set of buckets an error not
+// recognized by ErrorMsg falls into, since those buckets (rather than the
+// raw error message) are what end up as a span status or a Prometheus
+// label value.
+func TestUnknownErrorBucket(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"timeout", fakeTimeoutErr{}, "timeout"},
+		{"net.OpError", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, "network_error"},
+		{"bad handshake", websocket.ErrBadHandshake, "network_error"},
+		{"unexpected close", &websocket.CloseError{Code: websocket.CloseAbnormalClosure, Text: "boom"}, "network_error"},
+		{"anything else", errors.New("some gremlin server error"), "unknown"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := unknownErrorBucket(tc.err); got != tc.want {
+				t.Fatalf("unknownErrorBucket(%v) = %q, want %q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,22 @@ package gremlin
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 )
 
+// streamingGremlin is implemented by Gremlin_i backends that support
+// streaming query results, such as GremlinConnection.
+type streamingGremlin interface {
+	ExecStream(ctx context.Context, query string) (*ResultStream, error)
+}
+
+// GremlinTracer instruments a Gremlin_i with the now-archived opentracing-go
+// API.
+//
+// Deprecated: use GremlinOTel instead, which emits OpenTelemetry spans.
 type GremlinTracer struct {
 	next   Gremlin_i
 	tracer opentracing.Tracer
@@ -35,6 +46,19 @@ func (g GremlinTracer) ExecQueryF(ctx context.Context, gremlinQuery GremlinQuery
 	return g.next.ExecQueryF(ctx, gremlinQuery)
 }
 
+// ExecStream forwards to next's ExecStream if it implements one, tracing
+// the call the same way ExecQueryF is traced.
+func (g GremlinTracer) ExecStream(ctx context.Context, query string) (*ResultStream, error) {
+	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.ExecStream")
+	span, _ := StartSpanFromParent(ctx, g.tracer, method, opentracing.Tags{"type": "gremlin"})
+	defer span.Finish()
+	streamer, ok := g.next.(streamingGremlin)
+	if !ok {
+		return nil, errors.New("gremlin: underlying client does not support ExecStream")
+	}
+	return streamer.ExecStream(ctx, query)
+}
+
 func (g GremlinTracer) StartMonitor(ctx context.Context, interval time.Duration) (err error) {
 	method := CoalesceStrings(OpNameFromContext(ctx), "Gremlin.StartMonitor")
 	span, _ := StartSpanFromParent(ctx, g.tracer, method, opentracing.Tags{"type": "gremlin"})
@@ -0,0 +1,152 @@
+package gremlin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ResultStream iterates over the results of a query one item at a time,
+// decoding StatusPartialContent frames as they arrive off the websocket
+// instead of buffering the whole response the way GremlinConnection.Exec
+// does. Next returns io.EOF once the final StatusSuccess frame has been
+// consumed. If the server demands authentication mid-stream, the results
+// that follow the challenge are read in full before Next returns any of them.
+type ResultStream struct {
+	conn   *GremlinConnection
+	cancel context.CancelFunc
+	closed int32 // atomic; set by Close, checked without taking mu so Close never blocks on a read in flight
+
+	mu      sync.Mutex
+	pending []json.RawMessage
+	done    bool
+}
+
+// ExecStream runs query and returns a ResultStream yielding its results as
+// they arrive, rather than buffering the entire response in memory.
+func (c *GremlinConnection) ExecStream(ctx context.Context, query string) (*ResultStream, error) {
+	req, err := Query(query)
+	if err != nil {
+		return nil, err
+	}
+	requestMessage, err := GraphSONSerializer(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.Ws.WriteMessage(websocket.BinaryMessage, requestMessage); err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	s := &ResultStream{conn: c, cancel: cancel}
+	go func() {
+		<-streamCtx.Done()
+		// Unblock a read that's in flight when the caller closes the stream.
+		c.Ws.SetReadDeadline(time.Now())
+	}()
+	return s, nil
+}
+
+// Next returns the next result item, blocking until it is available.
+// It returns io.EOF once the stream is exhausted.
+func (s *ResultStream) Next() (json.RawMessage, error) {
+	if atomic.LoadInt32(&s.closed) != 0 {
+		return nil, io.EOF
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.pending) == 0 {
+		if s.done {
+			return nil, io.EOF
+		}
+		if err := s.fill(); err != nil {
+			return nil, err
+		}
+	}
+	item := s.pending[0]
+	s.pending = s.pending[1:]
+	return item, nil
+}
+
+// fill reads the next frame off the websocket, appending any decoded items
+// to pending. A StatusAuthenticate frame is answered through
+// GremlinConnection.Authenticate, which reads the rest of the response in
+// full; its items are queued at once and the stream is then done.
+func (s *ResultStream) fill() error {
+	for {
+		_, message, err := s.conn.Ws.ReadMessage()
+		if err != nil {
+			return err
+		}
+		var res *Response
+		if err = json.Unmarshal(message, &res); err != nil {
+			return err
+		}
+
+		switch res.Status.Code {
+		case StatusNoContent:
+			s.done = true
+			return nil
+
+		case StatusAuthenticate:
+			data, err := s.conn.Authenticate(res.RequestId)
+			if err != nil {
+				return err
+			}
+			s.done = true
+			if len(data) == 0 {
+				return nil
+			}
+			var items []json.RawMessage
+			if err = json.Unmarshal(data, &items); err != nil {
+				return err
+			}
+			s.pending = append(s.pending, items...)
+			return nil
+
+		case StatusPartialContent, StatusSuccess:
+			var items []json.RawMessage
+			if err = json.Unmarshal(res.Result.Data, &items); err != nil {
+				return err
+			}
+			s.pending = append(s.pending, items...)
+			if res.Status.Code == StatusSuccess {
+				s.done = true
+			}
+			return nil
+
+		default:
+			msg, exists := ErrorMsg[res.Status.Code]
+			if !exists {
+				return errors.New("An unknown error occured")
+			} else if !s.conn.VerboseLogging {
+				return errors.New(msg)
+			}
+			return fmt.Errorf("%d error: %s. See additional details below:\nMessage: %s", res.Status.Code, msg, res.Status.Message)
+		}
+	}
+}
+
+// Close cancels any in-flight read and releases the stream. It does not
+// close the underlying GremlinConnection, but it sets the connection's read
+// deadline to the time of the cancel, so later reads on it fail.
+//
+// Close must never block on s.mu: Next/fill can be holding it for the
+// duration of a blocking ReadMessage, and it's exactly that in-flight read
+// Close needs to interrupt.
+func (s *ResultStream) Close() error {
+	if !atomic.CompareAndSwapInt32(&s.closed, 0, 1) {
+		return nil
+	}
+	s.cancel()
+	return nil
+}
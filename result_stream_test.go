@@ -0,0 +1,42 @@
+package gremlin
+
+import (
+	"io"
+	"testing"
+)
+
+// TestResultStreamCloseStopsNext checks that once Close has run, Next
+// returns io.EOF immediately rather than touching the (by then possibly
+// already-closed) underlying connection.
+func TestResultStreamCloseStopsNext(t *testing.T) {
+	cancels := 0
+	s := &ResultStream{cancel: func() { cancels++ }}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if cancels != 1 {
+		t.Fatalf("cancel called %d times, want 1", cancels)
+	}
+
+	if _, err := s.Next(); err != io.EOF {
+		t.Fatalf("Next after Close = %v, want io.EOF", err)
+	}
+}
+
+// TestResultStreamCloseIdempotent checks that calling Close twice returns
+// nil both times and invokes cancel only once.
+func TestResultStreamCloseIdempotent(t *testing.T) {
+	cancels := 0
+	s := &ResultStream{cancel: func() { cancels++ }}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+	if cancels != 1 {
+		t.Fatalf("cancel called %d times across two Close calls, want 1", cancels)
+	}
+}